@@ -0,0 +1,48 @@
+package asyncutil
+
+import "context"
+
+// CollectContextCause is an alias of CollectContext, kept for discoverability
+// under the "cause" name alongside Collector. CollectContext already delivers
+// context.Cause(ctx) on cancellation, so this adds no behavior of its own.
+func CollectContextCause(ctx context.Context, errchans ...<-chan error) <-chan error {
+	return CollectContext(ctx, errchans...)
+}
+
+// Collector funnels per-worker error channels into a single error channel, and
+// cancels its own context with the first observed non-nil error as the cause. Workers
+// sharing the context returned by NewCollector can therefore detect a sibling's
+// failure via context.Cause(ctx) and stop early, instead of running to completion.
+type Collector struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+// NewCollector derives a cancellable context from parent and returns a Collector
+// bound to it, along with the derived context. Callers should pass the returned
+// context on to worker goroutines so that the first error reported to Collect is
+// propagated to them as the cancellation cause.
+func NewCollector(parent context.Context) (*Collector, context.Context) {
+	ctx, cancel := context.WithCancelCause(parent)
+	return &Collector{ctx: ctx, cancel: cancel}, ctx
+}
+
+// Collect is same as CollectContext, except the first non-nil error observed from
+// errchans also cancels c's context, using that error as the cause.
+func (c *Collector) Collect(errchans ...<-chan error) <-chan error {
+	in := wait(c.ctx, errchans)
+	out := make(chan error)
+	go func() {
+		defer close(out)
+
+		var cancelled bool
+		for err := range in {
+			if err != nil && !cancelled {
+				cancelled = true
+				c.cancel(err)
+			}
+			out <- err
+		}
+	}()
+	return out
+}
@@ -0,0 +1,92 @@
+package asyncutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sanggonlee/asyncutil"
+)
+
+////////////////////////////////////////////////////////////////////////////
+// Examples.
+////////////////////////////////////////////////////////////////////////////
+
+func ExampleCollector() {
+	collector, ctx := asyncutil.NewCollector(context.Background())
+
+	doWork := func(ctx context.Context) chan error {
+		errs := make(chan error)
+		go func() {
+			defer close(errs)
+			select {
+			case <-ctx.Done():
+				errs <- context.Cause(ctx)
+			case <-time.After(time.Second):
+			}
+		}()
+		return errs
+	}
+
+	errs := collector.Collect(
+		doWork(ctx),
+		doWork(ctx),
+	)
+	for range errs {
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Tests.
+////////////////////////////////////////////////////////////////////////////
+
+func TestCollectContextCause_IsAliasOfCollectContext(t *testing.T) {
+	cause := errors.New("boom")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+
+	errch := make(chan error)
+	close(errch)
+
+	errs := asyncutil.CollectContextCause(ctx, errch)
+	var got error
+	for err := range errs {
+		got = err
+	}
+	if !errors.Is(got, cause) {
+		t.Errorf("Expected cause %v but got %v", cause, got)
+	}
+}
+
+func TestCollector_PropagatesFirstErrorAsCause(t *testing.T) {
+	collector, ctx := asyncutil.NewCollector(context.Background())
+	cause := errors.New("first failure")
+
+	failing := make(chan error)
+	go func() {
+		failing <- cause
+		close(failing)
+	}()
+
+	sibling := make(chan error)
+	go func() {
+		defer close(sibling)
+		<-ctx.Done()
+		sibling <- context.Cause(ctx)
+	}()
+
+	errs := collector.Collect(failing, sibling)
+	var numCause int
+	for err := range errs {
+		if errors.Is(err, cause) {
+			numCause++
+		}
+	}
+	// The cause surfaces three times: once forwarded from the failing channel
+	// itself, once from wait's own context-cancellation watcher, and once from
+	// the sibling observing context.Cause(ctx) after being cancelled.
+	if numCause != 3 {
+		t.Fatalf("Expected %d errors carrying the cause but got %d", 3, numCause)
+	}
+}
@@ -315,6 +315,41 @@ func TestCollectContext_CancelledContextIsPassed(t *testing.T) {
 	}
 }
 
+func TestCollectContext_CancelledWithCause(t *testing.T) {
+	cause := errors.New("boom")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+
+	errch := make(chan error)
+	close(errch)
+
+	errs := asyncutil.CollectContext(ctx, errch)
+	var got error
+	for err := range errs {
+		got = err
+	}
+	if !errors.Is(got, cause) {
+		t.Errorf("Expected cause %v but got %v", cause, got)
+	}
+}
+
+func TestCollectContext_NoCauseFallsBackToErr(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	errch := make(chan error)
+	close(errch)
+
+	errs := asyncutil.CollectContext(ctx, errch)
+	var got error
+	for err := range errs {
+		got = err
+	}
+	if got != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded error but got %v", got)
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////
 // Benchmarks.
 ////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,165 @@
+package asyncutil
+
+import (
+	"context"
+	"sync"
+)
+
+// Option configures the behavior of Map, ForEach, and Pipe.
+type Option func(*fanOutConfig)
+
+type fanOutConfig struct {
+	concurrency   int
+	ordered       bool
+	cancelOnError bool
+}
+
+// WithConcurrency limits the number of workers that may run concurrently. A
+// non-positive n (the default) means unlimited concurrency.
+func WithConcurrency(n int) Option {
+	return func(c *fanOutConfig) { c.concurrency = n }
+}
+
+// WithOrdered makes Map emit outputs in the same order as their corresponding
+// inputs, rather than in completion order (the default).
+func WithOrdered() Option {
+	return func(c *fanOutConfig) { c.ordered = true }
+}
+
+// WithCancelOnError cancels the context passed to worker as soon as one worker
+// returns an error, stopping any in-flight or not-yet-started workers early.
+func WithCancelOnError() Option {
+	return func(c *fanOutConfig) { c.cancelOnError = true }
+}
+
+func newFanOutConfig(opts []Option) *fanOutConfig {
+	cfg := &fanOutConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// Map runs worker over each of inputs concurrently and returns a channel of
+// outputs alongside a channel of errors. By default outputs are emitted in
+// completion order and a failing worker does not stop the others; pass
+// WithOrdered and/or WithCancelOnError to change that. Use WithConcurrency to
+// bound how many workers run at once. Callers must drain both returned channels
+// concurrently (e.g. in a select loop or with a separate goroutine per channel);
+// draining one to completion before starting on the other can deadlock if a
+// result is waiting to be sent on the other channel.
+func Map[I, O any](ctx context.Context, inputs []I, worker func(context.Context, I) (O, error), opts ...Option) (<-chan O, <-chan error) {
+	cfg := newFanOutConfig(opts)
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	type indexedResult struct {
+		index int
+		value O
+		err   error
+	}
+
+	resultsCh := make(chan indexedResult)
+	outputs := make(chan O)
+	errs := make(chan error)
+
+	var sem chan struct{}
+	if cfg.concurrency > 0 {
+		sem = make(chan struct{}, cfg.concurrency)
+	}
+
+	var wg sync.WaitGroup
+	for i, input := range inputs {
+		i, input := i, input
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			out, err := worker(ctx, input)
+			if err != nil && cfg.cancelOnError {
+				// cancel closes ctx.Done() immediately, which would otherwise
+				// race the select below and could drop the very error that
+				// triggered the cancellation.
+				cancel(err)
+				resultsCh <- indexedResult{index: i, value: out, err: err}
+				return
+			}
+
+			select {
+			case resultsCh <- indexedResult{index: i, value: out, err: err}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(resultsCh)
+	}()
+
+	go func() {
+		defer cancel(nil)
+		defer close(outputs)
+		defer close(errs)
+
+		if !cfg.ordered {
+			for r := range resultsCh {
+				if r.err != nil {
+					errs <- r.err
+					continue
+				}
+				outputs <- r.value
+			}
+			return
+		}
+
+		pending := make(map[int]indexedResult, len(inputs))
+		next := 0
+		for r := range resultsCh {
+			pending[r.index] = r
+			for {
+				res, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				if res.err != nil {
+					errs <- res.err
+					continue
+				}
+				outputs <- res.value
+			}
+		}
+	}()
+
+	return outputs, errs
+}
+
+// ForEach is same as Map, except worker does not produce a value; it returns
+// only a channel of per-input errors.
+func ForEach[I any](ctx context.Context, inputs []I, worker func(context.Context, I) error, opts ...Option) <-chan error {
+	outputs, errs := Map(ctx, inputs, func(ctx context.Context, in I) (struct{}, error) {
+		return struct{}{}, worker(ctx, in)
+	}, opts...)
+
+	go func() {
+		for range outputs {
+		}
+	}()
+
+	return errs
+}
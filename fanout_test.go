@@ -0,0 +1,188 @@
+package asyncutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/sanggonlee/asyncutil"
+)
+
+////////////////////////////////////////////////////////////////////////////
+// Examples.
+////////////////////////////////////////////////////////////////////////////
+
+func ExampleMap() {
+	square := func(ctx context.Context, n int) (int, error) {
+		return n * n, nil
+	}
+
+	outputs, errs := asyncutil.Map(context.Background(), []int{1, 2, 3}, square, asyncutil.WithOrdered())
+
+	var squares []int
+	for out := range outputs {
+		squares = append(squares, out)
+	}
+	for range errs {
+	}
+	fmt.Println(squares)
+	// Output: [1 4 9]
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Tests.
+////////////////////////////////////////////////////////////////////////////
+
+func TestMap_OrderedPreservesInputOrder(t *testing.T) {
+	inputs := []int{5, 1, 4, 2, 3}
+	worker := func(ctx context.Context, n int) (int, error) {
+		return n, nil
+	}
+
+	outputs, errs := asyncutil.Map(context.Background(), inputs, worker, asyncutil.WithOrdered())
+
+	var got []int
+	for out := range outputs {
+		got = append(got, out)
+	}
+	for range errs {
+	}
+
+	if len(got) != len(inputs) {
+		t.Fatalf("Expected %d outputs but got %d", len(inputs), len(got))
+	}
+	for i, v := range inputs {
+		if got[i] != v {
+			t.Errorf("Expected output %d at index %d but got %d", v, i, got[i])
+		}
+	}
+}
+
+func TestMap_CollectsErrorsAlongsideOutputs(t *testing.T) {
+	wantErr := errors.New("boom")
+	inputs := []int{1, 2, 3}
+	worker := func(ctx context.Context, n int) (int, error) {
+		if n == 2 {
+			return 0, wantErr
+		}
+		return n, nil
+	}
+
+	outputs, errs := asyncutil.Map(context.Background(), inputs, worker)
+
+	var gotOutputs []int
+	outputsDone := make(chan struct{})
+	go func() {
+		defer close(outputsDone)
+		for out := range outputs {
+			gotOutputs = append(gotOutputs, out)
+		}
+	}()
+
+	var numErrors int
+	for err := range errs {
+		numErrors++
+		if !errors.Is(err, wantErr) {
+			t.Errorf("Expected error %v but got %v", wantErr, err)
+		}
+	}
+	<-outputsDone
+
+	sort.Ints(gotOutputs)
+	if len(gotOutputs) != 2 || gotOutputs[0] != 1 || gotOutputs[1] != 3 {
+		t.Errorf("Expected outputs [1 3] but got %v", gotOutputs)
+	}
+	if numErrors != 1 {
+		t.Fatalf("Expected %d error but got %d", 1, numErrors)
+	}
+}
+
+func TestMap_ConcurrencyLimit(t *testing.T) {
+	inputs := make([]int, 10)
+	sem := make(chan struct{}, 2)
+	worker := func(ctx context.Context, n int) (int, error) {
+		select {
+		case sem <- struct{}{}:
+		default:
+			return 0, errors.New("exceeded concurrency limit")
+		}
+		defer func() { <-sem }()
+		return n, nil
+	}
+
+	outputs, errs := asyncutil.Map(context.Background(), inputs, worker, asyncutil.WithConcurrency(2))
+	for range outputs {
+	}
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Worker observed more than the configured concurrency limit: %v", err)
+		}
+	}
+}
+
+func TestMap_CancelOnErrorDeliversTriggeringError(t *testing.T) {
+	wantErr := errors.New("boom")
+	inputs := make([]int, 200)
+	for i := range inputs {
+		inputs[i] = i
+	}
+	worker := func(ctx context.Context, n int) (int, error) {
+		if n == 0 {
+			return 0, wantErr
+		}
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+
+	// Regression test: cancel fires inside the triggering worker before it
+	// sends its result, so a racy select between the send and ctx.Done()
+	// would drop the error almost every time. Run it enough times that a
+	// reintroduced race would reliably show up as a failure.
+	for i := 0; i < 100; i++ {
+		outputs, errs := asyncutil.Map(context.Background(), inputs, worker, asyncutil.WithCancelOnError())
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for range outputs {
+			}
+		}()
+
+		var numTriggering int
+		for err := range errs {
+			if errors.Is(err, wantErr) {
+				numTriggering++
+			}
+		}
+		<-done
+		if numTriggering != 1 {
+			t.Fatalf("Run %d: expected %d triggering error but got %d", i, 1, numTriggering)
+		}
+	}
+}
+
+func TestForEach_CollectsErrors(t *testing.T) {
+	wantErr := errors.New("boom")
+	inputs := []int{1, 2, 3}
+	worker := func(ctx context.Context, n int) error {
+		if n == 2 {
+			return wantErr
+		}
+		return nil
+	}
+
+	var numErrors int
+	for err := range asyncutil.ForEach(context.Background(), inputs, worker) {
+		if err != nil {
+			numErrors++
+			if !errors.Is(err, wantErr) {
+				t.Errorf("Expected error %v but got %v", wantErr, err)
+			}
+		}
+	}
+	if numErrors != 1 {
+		t.Fatalf("Expected %d error but got %d", 1, numErrors)
+	}
+}
@@ -0,0 +1,121 @@
+package asyncutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DrainTimeoutError is sent on the channel returned by CollectContextGraceful
+// when grace elapses before all of its errchans finished draining.
+type DrainTimeoutError struct {
+	Grace time.Duration
+}
+
+func (e *DrainTimeoutError) Error() string {
+	return fmt.Sprintf("asyncutil: errchans did not drain within %s of context cancellation", e.Grace)
+}
+
+// CollectContextGraceful is same as CollectContext, except it fixes a bug in
+// wait: when ctx is already cancelled on entry, wait returns immediately and
+// ignores errchans entirely, leaking their goroutines if they later try to
+// send. CollectContextGraceful instead keeps draining errchans for up to grace
+// once ctx is cancelled, and only then closes the resulting channel. If
+// errchans have not all finished by the end of grace, a *DrainTimeoutError is
+// sent on the resulting channel before it closes.
+func CollectContextGraceful(ctx context.Context, grace time.Duration, errchans ...<-chan error) <-chan error {
+	return gracefulFanIn[error](ctx, grace, errchans,
+		func(err error) error { return err },
+		func(err error) bool { return err == nil },
+	)
+}
+
+// CollectResultsContextGraceful is same as CollectResultsContext, except it
+// fixes the same already-cancelled-ctx bug that CollectContextGraceful fixes
+// for CollectContext: it keeps draining chans for up to grace once ctx is
+// cancelled, and only then closes the resulting channel, instead of dropping
+// chans entirely and leaking their goroutines. If chans have not all finished
+// by the end of grace, a Result[T] carrying a *DrainTimeoutError as its Err is
+// sent on the resulting channel before it closes.
+func CollectResultsContextGraceful[T any](ctx context.Context, grace time.Duration, chans ...<-chan Result[T]) <-chan Result[T] {
+	return gracefulFanIn[Result[T]](ctx, grace, chans,
+		func(err error) Result[T] { return Result[T]{Err: err} },
+		nil,
+	)
+}
+
+// gracefulFanIn is the shared concurrency core behind CollectContextGraceful
+// and CollectResultsContextGraceful: it funnels chans into a single output
+// channel, and, once ctx is done, keeps draining chans for up to grace before
+// closing the output (sending wrapErr(context.Cause(ctx)) as soon as ctx is
+// done, and wrapErr(a *DrainTimeoutError) if chans haven't all finished by the
+// end of grace). skip, if non-nil, is used to drop values read from chans
+// before they reach the output.
+func gracefulFanIn[T any](ctx context.Context, grace time.Duration, chans []<-chan T, wrapErr func(error) T, skip func(T) bool) <-chan T {
+	out := make(chan T)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(len(chans))
+	for _, ch := range chans {
+		go func(c <-chan T) {
+			defer wg.Done()
+
+			if c == nil {
+				return
+			}
+
+			for v := range c {
+				if skip != nil && skip(v) {
+					continue
+				}
+				select {
+				case out <- v:
+				case <-stop:
+					return
+				}
+			}
+		}(ch)
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+
+	go func() {
+		defer close(out)
+		defer close(stop)
+
+		done := ctx.Done()
+		if isEmptyContext(ctx) || done == nil {
+			<-drained
+			return
+		}
+
+		select {
+		case <-drained:
+			// drained and done can become ready at roughly the same moment
+			// (e.g. zero chans, or chans that close immediately against an
+			// already-cancelled ctx); re-check ctx.Err() rather than racing the
+			// two, so a cancellation cause is never silently dropped just
+			// because select happened to pick this case.
+			if ctx.Err() == nil {
+				return
+			}
+		case <-done:
+		}
+
+		out <- wrapErr(context.Cause(ctx))
+
+		select {
+		case <-drained:
+		case <-time.After(grace):
+			out <- wrapErr(&DrainTimeoutError{Grace: grace})
+		}
+	}()
+
+	return out
+}
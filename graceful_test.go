@@ -0,0 +1,167 @@
+package asyncutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sanggonlee/asyncutil"
+)
+
+////////////////////////////////////////////////////////////////////////////
+// Examples.
+////////////////////////////////////////////////////////////////////////////
+
+func ExampleCollectContextGraceful() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errch := make(chan error)
+	go func() {
+		defer close(errch)
+		time.Sleep(50 * time.Millisecond)
+		errch <- nil
+	}()
+
+	cancel()
+	for err := range asyncutil.CollectContextGraceful(ctx, time.Second, errch) {
+		if err != nil {
+			_ = err
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Tests.
+////////////////////////////////////////////////////////////////////////////
+
+func TestCollectContextGraceful_DrainsInFlightErrchansAfterCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	wantErr := errors.New("late error")
+	errch := make(chan error)
+	go func() {
+		defer close(errch)
+		time.Sleep(100 * time.Millisecond)
+		errch <- wantErr
+	}()
+
+	var numLateErrors, numCancelErrors int
+	for err := range asyncutil.CollectContextGraceful(ctx, time.Second, errch) {
+		if errors.Is(err, wantErr) {
+			numLateErrors++
+		}
+		if errors.Is(err, context.Canceled) {
+			numCancelErrors++
+		}
+	}
+	if numLateErrors != 1 {
+		t.Errorf("Expected %d late error but got %d", 1, numLateErrors)
+	}
+	if numCancelErrors != 1 {
+		t.Errorf("Expected %d cancellation error but got %d", 1, numCancelErrors)
+	}
+}
+
+func TestCollectContextGraceful_DrainTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	errch := make(chan error)
+	t.Cleanup(func() { close(errch) })
+
+	var gotTimeout bool
+	for err := range asyncutil.CollectContextGraceful(ctx, 100*time.Millisecond, errch) {
+		var drainErr *asyncutil.DrainTimeoutError
+		if errors.As(err, &drainErr) {
+			gotTimeout = true
+		}
+	}
+	if !gotTimeout {
+		t.Error("Expected a DrainTimeoutError when errchan never finishes within the grace period")
+	}
+}
+
+func TestCollectContextGraceful_CauseAlwaysDeliveredWithNoErrchans(t *testing.T) {
+	// Regression test: with no errchans, drained closes almost immediately,
+	// racing the already-cancelled ctx's done channel. The cancellation cause
+	// must still be delivered every time, not just on average.
+	for i := 0; i < 2000; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var got error
+		for err := range asyncutil.CollectContextGraceful(ctx, time.Second) {
+			got = err
+		}
+		if !errors.Is(got, context.Canceled) {
+			t.Fatalf("Run %d: expected context.Canceled but got %v", i, got)
+		}
+	}
+}
+
+func TestCollectContextGraceful_NoCancellation(t *testing.T) {
+	errch := make(chan error)
+	go func() {
+		defer close(errch)
+		errch <- errors.New("err")
+	}()
+
+	var numErrors int
+	for err := range asyncutil.CollectContextGraceful(context.Background(), time.Second, errch) {
+		if err != nil {
+			numErrors++
+		}
+	}
+	if numErrors != 1 {
+		t.Errorf("Expected %d error but got %d", 1, numErrors)
+	}
+}
+
+func TestCollectResultsContextGraceful_DrainsInFlightChansAfterCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ch := make(chan asyncutil.Result[int])
+	go func() {
+		defer close(ch)
+		time.Sleep(100 * time.Millisecond)
+		ch <- asyncutil.Result[int]{Value: 1}
+	}()
+
+	var numLateValues, numCancelErrors int
+	for result := range asyncutil.CollectResultsContextGraceful(ctx, time.Second, ch) {
+		if result.Err == nil && result.Value == 1 {
+			numLateValues++
+		}
+		if errors.Is(result.Err, context.Canceled) {
+			numCancelErrors++
+		}
+	}
+	if numLateValues != 1 {
+		t.Errorf("Expected %d late value but got %d", 1, numLateValues)
+	}
+	if numCancelErrors != 1 {
+		t.Errorf("Expected %d cancellation error but got %d", 1, numCancelErrors)
+	}
+}
+
+func TestCollectResultsContextGraceful_DrainTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ch := make(chan asyncutil.Result[int])
+	t.Cleanup(func() { close(ch) })
+
+	var gotTimeout bool
+	for result := range asyncutil.CollectResultsContextGraceful(ctx, 100*time.Millisecond, ch) {
+		var drainErr *asyncutil.DrainTimeoutError
+		if errors.As(result.Err, &drainErr) {
+			gotTimeout = true
+		}
+	}
+	if !gotTimeout {
+		t.Error("Expected a DrainTimeoutError when chan never finishes within the grace period")
+	}
+}
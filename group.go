@@ -0,0 +1,94 @@
+package asyncutil
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs a collection of functions concurrently and waits for them to finish,
+// analogous to golang.org/x/sync/errgroup.Group. Unlike Collect/CollectContext,
+// which only funnel errors, Group fails fast: the first non-nil error returned by
+// any function cancels the group's context (via the same Collector plumbing used
+// by NewCollector) so that sibling functions can observe it and stop early, and
+// Wait returns that first error.
+type Group struct {
+	collector *Collector
+	ctx       context.Context
+
+	wg  sync.WaitGroup
+	sem chan struct{}
+
+	once sync.Once
+	err  error
+}
+
+// WithContext returns a new Group and a context derived from parent. The derived
+// context is cancelled, with the first error reported to Go/TryGo as its cause,
+// the moment that error is observed.
+func WithContext(parent context.Context) (*Group, context.Context) {
+	collector, ctx := NewCollector(parent)
+	g := &Group{collector: collector, ctx: ctx}
+	return g, ctx
+}
+
+// SetLimit sets the maximum number of functions that may be running concurrently,
+// enforced via a semaphore channel. A negative n removes the limit. SetLimit must
+// be called before Go or TryGo.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go runs f in a new goroutine, blocking until the concurrency limit set by
+// SetLimit allows another function to start.
+func (g *Group) Go(f func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.wg.Add(1)
+	go g.run(f)
+}
+
+// TryGo is same as Go, except it does not block when the concurrency limit set by
+// SetLimit has already been reached. It returns false without running f in that
+// case.
+func (g *Group) TryGo(f func(ctx context.Context) error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+
+	g.wg.Add(1)
+	go g.run(f)
+	return true
+}
+
+func (g *Group) run(f func(ctx context.Context) error) {
+	defer g.wg.Done()
+	if g.sem != nil {
+		defer func() { <-g.sem }()
+	}
+
+	if err := f(g.ctx); err != nil {
+		g.once.Do(func() {
+			g.err = err
+			g.collector.cancel(err)
+		})
+	}
+}
+
+// Wait blocks until all functions passed to Go or TryGo have returned, then
+// returns the first non-nil error among them, if any. It also cancels the
+// group's context, releasing any resources associated with it.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.collector.cancel(nil)
+	return g.err
+}
@@ -0,0 +1,151 @@
+package asyncutil_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sanggonlee/asyncutil"
+)
+
+////////////////////////////////////////////////////////////////////////////
+// Examples.
+////////////////////////////////////////////////////////////////////////////
+
+func ExampleGroup() {
+	g, ctx := asyncutil.WithContext(context.Background())
+
+	urls := []string{"1", "2", "3"}
+	for _, url := range urls {
+		url := url
+		g.Go(func(ctx context.Context) error {
+			_, err := http.Get(url)
+			return err
+		})
+	}
+	_ = ctx
+
+	if err := g.Wait(); err != nil {
+		panic(err)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Tests.
+////////////////////////////////////////////////////////////////////////////
+
+func TestGroup_NoError(t *testing.T) {
+	g, _ := asyncutil.WithContext(context.Background())
+	for i := 0; i < 3; i++ {
+		g.Go(func(ctx context.Context) error {
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+}
+
+func TestGroup_FirstErrorIsReturned(t *testing.T) {
+	g, _ := asyncutil.WithContext(context.Background())
+	wantErr := errors.New("boom")
+
+	g.Go(func(ctx context.Context) error {
+		return nil
+	})
+	g.Go(func(ctx context.Context) error {
+		return wantErr
+	})
+
+	if err := g.Wait(); !errors.Is(err, wantErr) {
+		t.Errorf("Expected error %v but got %v", wantErr, err)
+	}
+}
+
+func TestGroup_CancelsContextOnFirstError(t *testing.T) {
+	g, ctx := asyncutil.WithContext(context.Background())
+	wantErr := errors.New("boom")
+
+	done := make(chan struct{})
+	g.Go(func(ctx context.Context) error {
+		return wantErr
+	})
+	g.Go(func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			close(done)
+		case <-time.After(2 * time.Second):
+		}
+		return ctx.Err()
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected sibling to observe context cancellation")
+	}
+
+	if cause := context.Cause(ctx); !errors.Is(cause, wantErr) {
+		t.Errorf("Expected cause %v but got %v", wantErr, cause)
+	}
+
+	_ = g.Wait()
+}
+
+func TestGroup_SetLimit(t *testing.T) {
+	g, _ := asyncutil.WithContext(context.Background())
+	g.SetLimit(2)
+
+	var running, maxRunning int32
+	var mu = make(chan struct{}, 1)
+	mu <- struct{}{}
+
+	inc := func(delta int32) {
+		<-mu
+		running += delta
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu <- struct{}{}
+	}
+
+	for i := 0; i < 5; i++ {
+		g.Go(func(ctx context.Context) error {
+			inc(1)
+			time.Sleep(50 * time.Millisecond)
+			inc(-1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if maxRunning > 2 {
+		t.Errorf("Expected at most %d concurrent functions but observed %d", 2, maxRunning)
+	}
+}
+
+func TestGroup_TryGoRespectsLimit(t *testing.T) {
+	g, _ := asyncutil.WithContext(context.Background())
+	g.SetLimit(1)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	g.Go(func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	if g.TryGo(func(ctx context.Context) error { return nil }) {
+		t.Errorf("Expected TryGo to fail when the limit has been reached")
+	}
+
+	close(release)
+	if err := g.Wait(); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+}
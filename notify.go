@@ -0,0 +1,16 @@
+package asyncutil
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+// NotifyContext returns a context that is cancelled the moment one of the given
+// signals is received, along with a stop function that releases the associated
+// resources. Callers should always call stop, typically via defer, once they no
+// longer need to observe the signals.
+func NotifyContext(parent context.Context, signals ...os.Signal) (context.Context, func()) {
+	ctx, stop := signal.NotifyContext(parent, signals...)
+	return ctx, stop
+}
@@ -0,0 +1,53 @@
+package asyncutil_test
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sanggonlee/asyncutil"
+)
+
+////////////////////////////////////////////////////////////////////////////
+// Examples.
+////////////////////////////////////////////////////////////////////////////
+
+func ExampleNotifyContext() {
+	ctx, stop := asyncutil.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	<-ctx.Done()
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Tests.
+////////////////////////////////////////////////////////////////////////////
+
+func TestNotifyContext_CancelledOnSignal(t *testing.T) {
+	ctx, stop := asyncutil.NotifyContext(context.Background(), syscall.SIGUSR1)
+	defer stop()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected context to be cancelled on signal")
+	}
+}
+
+func TestNotifyContext_StopCancelsContext(t *testing.T) {
+	ctx, stop := asyncutil.NotifyContext(context.Background(), syscall.SIGUSR2)
+	stop()
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Error("Expected stop to cancel the context")
+	}
+}
@@ -0,0 +1,191 @@
+package asyncutil
+
+import (
+	"context"
+	"sync"
+)
+
+// Stage transforms a single value of type I into a value of type O. Returning
+// ok=false drops the value from the pipeline without reporting an error, which
+// is how a filtering stage is expressed. A non-nil err is reported on the
+// stage's error channel; unless the stage runs under WithCancelOnError, it does
+// not otherwise stop sibling values in flight.
+type Stage[I, O any] func(ctx context.Context, in I) (out O, ok bool, err error)
+
+// Source turns items into a channel suitable as the first stage of a pipeline.
+// Pass the same ctx to every Pipe stage chained after it, so a cancellation
+// triggered downstream (e.g. by WithCancelOnError) unblocks Source too instead
+// of leaking its goroutine on an abandoned send.
+func Source[T any](ctx context.Context, items []T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, item := range items {
+			select {
+			case out <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Pipe runs stage over every value received from in, honoring WithConcurrency
+// and WithCancelOnError the same way Map does, and returns the transformed
+// values alongside any errors reported by stage. WithOrdered has no effect on
+// Pipe: values are emitted in completion order. Chaining calls to Pipe is how a Pipeline composes "source -> map ->
+// filter -> collect" without the caller hand-writing goroutines and channels.
+//
+// ctx and cancel must be the same pair shared by every stage of the chain,
+// including Source (e.g. from ctx, cancel := context.WithCancelCause(parent)):
+// that is what lets WithCancelOnError unblock upstream producers when it fires,
+// instead of leaking a goroutine parked on a send nobody will ever receive. Pass
+// a nil cancel if no stage in the chain uses WithCancelOnError.
+//
+// Callers must drain both outputs and errs concurrently (e.g. in a select loop
+// or with a separate goroutine per channel); draining one to completion before
+// starting on the other can deadlock if a result is waiting to be sent on the
+// other channel.
+func Pipe[I, O any](ctx context.Context, cancel context.CancelCauseFunc, in <-chan I, stage Stage[I, O], opts ...Option) (<-chan O, <-chan error) {
+	cfg := newFanOutConfig(opts)
+
+	outputs := make(chan O)
+	errs := make(chan error)
+
+	var sem chan struct{}
+	if cfg.concurrency > 0 {
+		sem = make(chan struct{}, cfg.concurrency)
+	}
+
+	var wg sync.WaitGroup
+	go func() {
+		defer func() {
+			wg.Wait()
+			close(outputs)
+			close(errs)
+		}()
+
+		for {
+			select {
+			case v, open := <-in:
+				if !open {
+					return
+				}
+				if sem != nil {
+					select {
+					case sem <- struct{}{}:
+					case <-ctx.Done():
+						return
+					}
+				}
+				wg.Add(1)
+				go func(v I) {
+					defer wg.Done()
+					if sem != nil {
+						defer func() { <-sem }()
+					}
+
+					out, ok, err := stage(ctx, v)
+					if err != nil {
+						if cfg.cancelOnError && cancel != nil {
+							// cancel closes ctx.Done() immediately, which would
+							// otherwise race the send below and could drop the
+							// very error that triggered the cancellation.
+							cancel(err)
+							errs <- err
+							return
+						}
+						select {
+						case errs <- err:
+						case <-ctx.Done():
+						}
+						return
+					}
+					if !ok {
+						return
+					}
+					select {
+					case outputs <- out:
+					case <-ctx.Done():
+					}
+				}(v)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return outputs, errs
+}
+
+// Pipeline wires a chain of stages sharing one ctx/cancel pair, so composing
+// "source -> map -> filter -> collect" via NewPipeline, Then, and Collect
+// doesn't require the caller to derive a cancel context or thread it through
+// Source and every Pipe call by hand the way chaining Pipe directly does.
+type Pipeline[T any] struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	out    <-chan T
+	errs   <-chan error
+}
+
+// NewPipeline starts a Pipeline from items, deriving the cancel context that
+// Then and WithCancelOnError share for the rest of the chain.
+func NewPipeline[T any](ctx context.Context, items []T) *Pipeline[T] {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Pipeline[T]{
+		ctx:    ctx,
+		cancel: cancel,
+		out:    Source(ctx, items),
+	}
+}
+
+// Then runs stage over p's values via Pipe, honoring opts the same way Pipe
+// does (WithOrdered has no effect), and returns a Pipeline carrying the
+// transformed values onward. Go
+// does not allow a method to introduce a new type parameter, so Then is a
+// package-level function rather than a method on Pipeline.
+func Then[I, O any](p *Pipeline[I], stage Stage[I, O], opts ...Option) *Pipeline[O] {
+	out, errs := Pipe(p.ctx, p.cancel, p.out, stage, opts...)
+	return &Pipeline[O]{
+		ctx:    p.ctx,
+		cancel: p.cancel,
+		out:    out,
+		errs:   wait(context.TODO(), []<-chan error{p.errs, errs}),
+	}
+}
+
+// Collect drains p into a slice, returning early with the first error
+// observed from any stage in the chain or, if it fires first, the cause of
+// the chain's shared context being cancelled.
+func (p *Pipeline[T]) Collect() ([]T, error) {
+	return PipelineCollect(p.ctx, p.out, p.errs)
+}
+
+// PipelineCollect drains in into a slice, returning early with the first error
+// observed on errs or, if it fires first, context.Cause(ctx).
+func PipelineCollect[T any](ctx context.Context, in <-chan T, errs <-chan error) ([]T, error) {
+	var out []T
+	for in != nil || errs != nil {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+			out = append(out, v)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				return out, err
+			}
+		case <-ctx.Done():
+			return out, context.Cause(ctx)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,205 @@
+package asyncutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sanggonlee/asyncutil"
+)
+
+////////////////////////////////////////////////////////////////////////////
+// Examples.
+////////////////////////////////////////////////////////////////////////////
+
+func ExamplePipe() {
+	ctx := context.Background()
+	source := asyncutil.Source(ctx, []int{1, 2, 3, 4, 5})
+
+	evens, _ := asyncutil.Pipe(ctx, nil, source, func(ctx context.Context, n int) (int, bool, error) {
+		return n, n%2 == 0, nil
+	})
+
+	doubled, errs := asyncutil.Pipe(ctx, nil, evens, func(ctx context.Context, n int) (int, bool, error) {
+		return n * 2, true, nil
+	})
+
+	results, _ := asyncutil.PipelineCollect(ctx, doubled, errs)
+	sort.Ints(results)
+	fmt.Println(results)
+	// Output: [4 8]
+}
+
+func ExamplePipeline() {
+	p := asyncutil.NewPipeline(context.Background(), []int{1, 2, 3, 4, 5})
+
+	evens := asyncutil.Then(p, func(ctx context.Context, n int) (int, bool, error) {
+		return n, n%2 == 0, nil
+	})
+
+	doubled := asyncutil.Then(evens, func(ctx context.Context, n int) (int, bool, error) {
+		return n * 2, true, nil
+	})
+
+	results, _ := doubled.Collect()
+	sort.Ints(results)
+	fmt.Println(results)
+	// Output: [4 8]
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Tests.
+////////////////////////////////////////////////////////////////////////////
+
+func TestPipe_MapStage(t *testing.T) {
+	ctx := context.Background()
+	source := asyncutil.Source(ctx, []int{1, 2, 3})
+
+	squares, errs := asyncutil.Pipe(ctx, nil, source, func(ctx context.Context, n int) (int, bool, error) {
+		return n * n, true, nil
+	})
+
+	results, err := asyncutil.PipelineCollect(ctx, squares, errs)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	sort.Ints(results)
+	if len(results) != 3 || results[0] != 1 || results[1] != 4 || results[2] != 9 {
+		t.Errorf("Expected [1 4 9] but got %v", results)
+	}
+}
+
+func TestPipe_FilterStage(t *testing.T) {
+	ctx := context.Background()
+	source := asyncutil.Source(ctx, []int{1, 2, 3, 4, 5, 6})
+
+	evens, errs := asyncutil.Pipe(ctx, nil, source, func(ctx context.Context, n int) (int, bool, error) {
+		return n, n%2 == 0, nil
+	})
+
+	results, err := asyncutil.PipelineCollect(ctx, evens, errs)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	sort.Ints(results)
+	if len(results) != 3 || results[0] != 2 || results[1] != 4 || results[2] != 6 {
+		t.Errorf("Expected [2 4 6] but got %v", results)
+	}
+}
+
+func TestPipe_ConcurrencyLimit(t *testing.T) {
+	ctx := context.Background()
+	items := make([]int, 20)
+	source := asyncutil.Source(ctx, items)
+
+	var running, maxRunning int32
+	outputs, errs := asyncutil.Pipe(ctx, nil, source, func(ctx context.Context, n int) (int, bool, error) {
+		cur := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if cur <= m || atomic.CompareAndSwapInt32(&maxRunning, m, cur) {
+				break
+			}
+		}
+		return n, true, nil
+	}, asyncutil.WithConcurrency(3))
+
+	if _, err := asyncutil.PipelineCollect(ctx, outputs, errs); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if maxRunning > 3 {
+		t.Errorf("Expected at most %d concurrent stage calls but observed %d", 3, maxRunning)
+	}
+}
+
+func TestPipe_CancelOnErrorUnblocksUpstreamSource(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	items := make([]int, 1000)
+	source := asyncutil.Source(ctx, items)
+
+	wantErr := errors.New("boom")
+	var failed int32
+	outputs, errs := asyncutil.Pipe(ctx, cancel, source, func(ctx context.Context, n int) (int, bool, error) {
+		if atomic.CompareAndSwapInt32(&failed, 0, 1) {
+			return 0, false, wantErr
+		}
+		return n, true, nil
+	}, asyncutil.WithCancelOnError(), asyncutil.WithConcurrency(1))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range outputs {
+		}
+	}()
+	var numErrors int
+	for err := range errs {
+		if errors.Is(err, wantErr) {
+			numErrors++
+		}
+	}
+	<-done
+	if numErrors != 1 {
+		t.Fatalf("Expected %d error but got %d", 1, numErrors)
+	}
+
+	// Source must observe the shared ctx being cancelled and stop trying to
+	// feed further values into the now-abandoned pipeline, instead of leaking
+	// its goroutine blocked on a send nobody will ever receive.
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline+1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > baseline+1 {
+		t.Errorf("Expected goroutine count to settle near baseline %d but got %d (source likely leaked)", baseline, n)
+	}
+}
+
+func TestPipeline_CollectReturnsStageError(t *testing.T) {
+	wantErr := errors.New("boom")
+	p := asyncutil.NewPipeline(context.Background(), []int{1, 2, 3})
+
+	doubled := asyncutil.Then(p, func(ctx context.Context, n int) (int, bool, error) {
+		if n == 2 {
+			return 0, false, wantErr
+		}
+		return n * 2, true, nil
+	})
+
+	results, err := doubled.Collect()
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Expected error %v but got %v", wantErr, err)
+	}
+	for _, r := range results {
+		if r != 2 && r != 6 {
+			t.Errorf("Unexpected result %d; only inputs 1 and 3 should ever succeed", r)
+		}
+	}
+}
+
+func TestPipelineCollect_ReturnsFirstError(t *testing.T) {
+	ctx := context.Background()
+
+	out := make(chan int)
+	errs := make(chan error, 1)
+	close(out)
+	wantErr := fmt.Errorf("boom")
+	errs <- wantErr
+	close(errs)
+
+	results, err := asyncutil.PipelineCollect(ctx, out, errs)
+	if err != wantErr {
+		t.Errorf("Expected error %v but got %v", wantErr, err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results but got %v", results)
+	}
+}
@@ -0,0 +1,36 @@
+package asyncutil
+
+import "context"
+
+// Result carries either a value produced by a worker or the error that prevented
+// it from producing one.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// CollectResults is same as Collect, except it fans in typed results rather than
+// bare errors, so values produced alongside success don't need to be smuggled
+// through a side channel. Results are forwarded in the order they arrive on their
+// source channel, per source channel.
+func CollectResults[T any](chans ...<-chan Result[T]) <-chan Result[T] {
+	return waitResults[T](context.TODO(), chans)
+}
+
+// CollectResultsContext is same as CollectResults, except it takes a context. If
+// at least one channel is given and the context exceeds deadline or is
+// cancelled, the resulting channel receives a Result[T] carrying
+// context.Cause(ctx) as its Err, in addition to the results collected from
+// chans; with no chans, nothing is sent. If the context was already cancelled by the time
+// CollectResultsContext is executed, the resulting channel will only contain that
+// one result, and not the results collected from chans.
+func CollectResultsContext[T any](ctx context.Context, chans ...<-chan Result[T]) <-chan Result[T] {
+	return waitResults[T](ctx, chans)
+}
+
+func waitResults[T any](ctx context.Context, chans []<-chan Result[T]) <-chan Result[T] {
+	return waitFanIn(ctx, chans,
+		func(ctx context.Context) Result[T] { return Result[T]{Err: context.Cause(ctx)} },
+		nil,
+	)
+}
@@ -0,0 +1,145 @@
+package asyncutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sanggonlee/asyncutil"
+)
+
+////////////////////////////////////////////////////////////////////////////
+// Examples.
+////////////////////////////////////////////////////////////////////////////
+
+func ExampleCollectResults() {
+	doWork := func(n int) chan asyncutil.Result[int] {
+		results := make(chan asyncutil.Result[int])
+		go func(n int) {
+			defer close(results)
+			results <- asyncutil.Result[int]{Value: n * n}
+		}(n)
+		return results
+	}
+
+	for result := range asyncutil.CollectResults(
+		doWork(1),
+		doWork(2),
+	) {
+		if result.Err != nil {
+			fmt.Println("Error:", result.Err)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Tests.
+////////////////////////////////////////////////////////////////////////////
+
+func TestCollectResults_NoChannels(t *testing.T) {
+	results := asyncutil.CollectResults[int]()
+	var n int
+	for range results {
+		n++
+	}
+	if n != 0 {
+		t.Errorf("Expected no results but got %d", n)
+	}
+}
+
+func TestCollectResults_ValuesAndErrorsArePreserved(t *testing.T) {
+	wantErr := errors.New("err")
+	results := asyncutil.CollectResults(
+		func() chan asyncutil.Result[int] {
+			ch := make(chan asyncutil.Result[int])
+			go func() {
+				defer close(ch)
+				ch <- asyncutil.Result[int]{Value: 1}
+			}()
+			return ch
+		}(),
+		func() chan asyncutil.Result[int] {
+			ch := make(chan asyncutil.Result[int])
+			go func() {
+				defer close(ch)
+				ch <- asyncutil.Result[int]{Err: wantErr}
+			}()
+			return ch
+		}(),
+	)
+
+	var values []int
+	var numErrors int
+	for result := range results {
+		if result.Err != nil {
+			numErrors++
+			if !errors.Is(result.Err, wantErr) {
+				t.Errorf("Expected error %v but got %v", wantErr, result.Err)
+			}
+			continue
+		}
+		values = append(values, result.Value)
+	}
+	if len(values) != 1 || values[0] != 1 {
+		t.Errorf("Expected values %v but got %v", []int{1}, values)
+	}
+	if numErrors != 1 {
+		t.Fatalf("Expected %d errors but got %d", 1, numErrors)
+	}
+}
+
+func TestCollectResultsContext_FunctionExceedsDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	results := asyncutil.CollectResultsContext(ctx,
+		func() chan asyncutil.Result[int] {
+			ch := make(chan asyncutil.Result[int])
+			go func() {
+				defer close(ch)
+				time.Sleep(time.Second)
+				ch <- asyncutil.Result[int]{Value: 1}
+			}()
+			return ch
+		}(),
+	)
+
+	var numTimeouts int
+	for result := range results {
+		if errors.Is(result.Err, context.DeadlineExceeded) {
+			numTimeouts++
+		}
+	}
+	if numTimeouts != 1 {
+		t.Errorf("Expected %d deadline exceeded results but got %d", 1, numTimeouts)
+	}
+}
+
+func TestCollectResultsContext_CancelledContextIsPassed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := asyncutil.CollectResultsContext(ctx,
+		func() chan asyncutil.Result[int] {
+			ch := make(chan asyncutil.Result[int])
+			go func() {
+				defer close(ch)
+				ch <- asyncutil.Result[int]{Value: 1}
+			}()
+			return ch
+		}(),
+	)
+
+	var n int
+	for result := range results {
+		n++
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("Expected context canceled error but got %v", result.Err)
+		}
+	}
+	if n != 1 {
+		t.Fatalf("Expected %d result but got %d", 1, n)
+	}
+}
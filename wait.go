@@ -0,0 +1,70 @@
+package asyncutil
+
+import (
+	"context"
+	"sync"
+)
+
+// waitFanIn is the shared concurrency core behind wait and waitResults: it funnels
+// chans into a single output channel, and, if ctx is not context.TODO, has a
+// non-nil Done channel and chans is non-empty, appends onCancel(ctx) to the
+// output once ctx is done. In that case, if ctx was already done by the time
+// waitFanIn is called, the output channel will only contain onCancel's value,
+// and not the values collected from chans. skip, if non-nil, is used to
+// drop values read from chans before they reach the output (e.g. wait drops nil
+// errors; waitResults forwards everything, so it passes a nil skip).
+func waitFanIn[T any](ctx context.Context, chans []<-chan T, onCancel func(context.Context) T, skip func(T) bool) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	closeChanEventually := func() {
+		wg.Wait()
+		close(out)
+	}
+
+	if !isEmptyContext(ctx) && len(chans) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			done := ctx.Done()
+			if done == nil {
+				return
+			}
+
+			<-done
+			out <- onCancel(ctx)
+		}()
+
+		if ctx.Err() != nil {
+			go closeChanEventually()
+			// Context is already done, return without waiting for chans
+			return out
+		}
+	}
+
+	for _, ch := range chans {
+		wg.Add(1)
+		go func(c <-chan T) {
+			defer wg.Done()
+
+			if c == nil {
+				return
+			}
+
+			for v := range c {
+				if skip != nil && skip(v) {
+					continue
+				}
+				out <- v
+			}
+		}(ch)
+	}
+
+	go closeChanEventually()
+
+	return out
+}
+
+func isEmptyContext(ctx context.Context) bool {
+	return ctx == context.TODO()
+}